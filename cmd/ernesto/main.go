@@ -0,0 +1,102 @@
+// Command ernesto runs the GithubRepository controller: it wires together
+// the Kubernetes client, credential resolver, webhook receiver, and metrics
+// server, then hands off to controller-runtime. Dependencies are built here
+// and passed into constructors rather than reached for with globals, so
+// pkg/controller stays usable outside a running manager (tests, other
+// entrypoints).
+package main
+
+import (
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	ctrlsource "sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/AlfredDobradi/go-ernesto/pkg/auth"
+	"github.com/AlfredDobradi/go-ernesto/pkg/config"
+	ernestocontroller "github.com/AlfredDobradi/go-ernesto/pkg/controller"
+	"github.com/AlfredDobradi/go-ernesto/pkg/k8sclient"
+	"github.com/AlfredDobradi/go-ernesto/pkg/metrics"
+	"github.com/AlfredDobradi/go-ernesto/pkg/webhook"
+)
+
+// webhookAddr is the bind address for the webhook HTTP server.
+const webhookAddr = ":8090"
+
+// metricsAddr is the bind address for the /metrics, /healthz, and /readyz endpoints.
+const metricsAddr = ":8091"
+
+func main() {
+	ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
+
+	restConfig, err := k8sclient.RestConfig()
+	if err != nil {
+		log.Log.Error(err, "failed to resolve kubernetes config")
+		os.Exit(1)
+	}
+
+	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{})
+	if err != nil {
+		log.Log.Error(err, "failed to create manager")
+		os.Exit(1)
+	}
+
+	clientset, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		log.Log.Error(err, "failed to create kubernetes clientset")
+		os.Exit(1)
+	}
+
+	reconciler := ernestocontroller.New(
+		mgr.GetClient(),
+		auth.NewResolver(clientset),
+		mgr.GetEventRecorderFor("ernesto"),
+		os.Getenv("ERNESTO_WEBHOOK_BASE_URL"),
+	)
+
+	watched := &unstructured.Unstructured{}
+	watched.SetGroupVersionKind(config.GithubRepositoryGVK)
+
+	err = ctrl.NewControllerManagedBy(mgr).
+		For(watched).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(reconciler.MapSecretToRequests)).
+		WatchesRawSource(ctrlsource.Channel(reconciler.WebhookEvents(), &handler.EnqueueRequestForObject{})).
+		WithOptions(controller.Options{MaxConcurrentReconciles: 4}).
+		Complete(reconciler)
+	if err != nil {
+		log.Log.Error(err, "failed to build controller")
+		os.Exit(1)
+	}
+
+	webhookServer := &webhook.Server{
+		Addr:    webhookAddr,
+		Secret:  reconciler.ResolveWebhookSecret,
+		Handler: reconciler.HandleWebhookEvent,
+	}
+	go func() {
+		if err := webhookServer.ListenAndServe(); err != nil {
+			log.Log.Error(err, "webhook server exited with error")
+		}
+	}()
+
+	metricsServer := &metrics.Server{Addr: metricsAddr}
+	go func() {
+		if err := metricsServer.ListenAndServe(); err != nil {
+			log.Log.Error(err, "metrics server exited with error")
+		}
+	}()
+
+	log.Log.Info("Ernesto initialized")
+
+	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+		log.Log.Error(err, "manager exited with error")
+		os.Exit(1)
+	}
+}
@@ -8,174 +8,187 @@ import (
 	"time"
 
 	"github.com/davecgh/go-spew/spew"
-	"github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/plumbing/transport/http"
-	"github.com/go-git/go-git/v5/storage/memory"
+	"go.opentelemetry.io/otel"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
-	"k8s.io/client-go/rest"
-	"k8s.io/client-go/util/retry"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/AlfredDobradi/go-ernesto/pkg/auth"
+	ernestoconfig "github.com/AlfredDobradi/go-ernesto/pkg/config"
+	ernestoerrors "github.com/AlfredDobradi/go-ernesto/pkg/errors"
+	"github.com/AlfredDobradi/go-ernesto/pkg/k8sclient"
+	"github.com/AlfredDobradi/go-ernesto/pkg/metrics"
+	"github.com/AlfredDobradi/go-ernesto/pkg/source"
 )
 
-type client struct {
-	*dynamic.DynamicClient
-}
+var tracer = otel.Tracer("github.com/AlfredDobradi/go-ernesto/cronjob")
+
+// metricsAddr is the bind address for the /metrics, /healthz, and /readyz endpoints.
+const metricsAddr = ":8091"
+
+// maxConcurrentSyncs bounds how many repositories are processed at once,
+// mirroring the controller's MaxConcurrentReconciles so a large namespace
+// can't fan out into an unbounded number of simultaneous clones.
+const maxConcurrentSyncs = 4
 
 func main() {
 	ctx := context.Background()
 
-	config, err := rest.InClusterConfig()
+	restConfig, err := k8sclient.RestConfig()
+	if err != nil {
+		slog.Error("Failed to resolve kubernetes config", "error", ernestoerrors.WithStack(err))
+		os.Exit(1)
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
 	if err != nil {
-		slog.Error("Failed to initialize in-cluster config", "error", err)
+		slog.Error("Failed to create client set", "error", ernestoerrors.WithStack(err))
 		os.Exit(1)
 	}
 
-	clientSet, err := dynamic.NewForConfig(config)
+	clientset, err := kubernetes.NewForConfig(restConfig)
 	if err != nil {
-		slog.Error("Failed to create client set", "error", err)
+		slog.Error("Failed to create kubernetes clientset", "error", ernestoerrors.WithStack(err))
 		os.Exit(1)
 	}
+	authResolver := auth.NewResolver(clientset)
 
 	slog.Info("Ernesto initialized")
 
-	cc := &client{clientSet}
+	metricsServer := &metrics.Server{Addr: metricsAddr}
+	go func() {
+		if err := metricsServer.ListenAndServe(); err != nil {
+			slog.Error("metrics server exited with error", "error", err)
+		}
+	}()
 
-	repos, err := cc.getRepos(ctx)
+	repos, err := getRepos(ctx, dynamicClient)
 	if err != nil {
-		slog.Error("Failed to get repos", "error", err)
-	} else {
-		spew.Dump(repos)
+		slog.Error("Failed to get repos", "error", ernestoerrors.Wrap(err, "list GithubRepositories"))
+		os.Exit(1)
 	}
+	spew.Dump(repos)
 
 	wg := &sync.WaitGroup{}
+	sem := make(chan struct{}, maxConcurrentSyncs)
 
 	for _, repo := range repos {
 		wg.Add(1)
-		go cc.processRepo(ctx, wg, repo)
+		sem <- struct{}{}
+		go func(repo ernestoconfig.Repository) {
+			defer func() { <-sem }()
+			processRepo(ctx, dynamicClient, authResolver, wg, repo)
+		}(repo)
 	}
 
 	wg.Wait()
 }
 
-type Repository struct {
-	Name         string
-	Namespace    string
-	URL          string
-	AccessToken  string
-	Username     string
-	Unstructured unstructured.Unstructured
-}
-
-func (c *client) getRepos(ctx context.Context) ([]Repository, error) {
-	resource := schema.GroupVersionResource{
-		Group:    "0x42.in",
-		Version:  "v1alpha1",
-		Resource: "githubrepositories",
-	}
-	repos, err := c.
-		Resource(resource).
+// getRepos lists every GithubRepository in the tacos namespace. It takes a
+// dynamic.Interface rather than a concrete client so it can run against a
+// fake dynamic client in tests.
+func getRepos(ctx context.Context, dynamicClient dynamic.Interface) ([]ernestoconfig.Repository, error) {
+	repos, err := dynamicClient.
+		Resource(ernestoconfig.GithubRepositoriesGVR).
 		Namespace("tacos").
 		List(ctx, metav1.ListOptions{})
 	if err != nil {
-		return nil, err
+		return nil, ernestoerrors.Classify(ernestoerrors.Wrap(err, "list GithubRepositories"), ernestoerrors.Transient)
 	}
 
-	repositories := make([]Repository, len(repos.Items))
-	for i, repo := range repos.Items {
-		slog.Info("Found repo", "name", repo.Object["metadata"].(map[string]any)["name"])
+	repositories := make([]ernestoconfig.Repository, 0, len(repos.Items))
+	for i := range repos.Items {
+		item := &repos.Items[i]
+		slog.Info("Found repo", "name", item.GetName())
 
-		spec := repo.Object["spec"].(map[string]any)
-
-		repositories[i] = Repository{
-			Name:         repo.GetName(),
-			Namespace:    repo.GetNamespace(),
-			URL:          spec["repoUrl"].(string),
-			AccessToken:  spec["accessToken"].(string),
-			Username:     spec["username"].(string),
-			Unstructured: repo,
+		repo, err := ernestoconfig.FromUnstructured(item)
+		if err != nil {
+			slog.Warn("skipping invalid GithubRepository spec", "name", item.GetName(), "error", err)
+			continue
 		}
+		repositories = append(repositories, repo)
 	}
 
 	return repositories, nil
 }
 
-func getLatestCommit(ctx context.Context, repo Repository) (string, error) {
-	r, err := git.Clone(memory.NewStorage(), nil, &git.CloneOptions{
-		URL: repo.URL,
-		Auth: &http.BasicAuth{
-			Username: repo.Username,
-			Password: repo.AccessToken,
-		},
-	})
+// processRepo resolves repo's latest commit and patches it onto the
+// GithubRepository's status subresource — the same config.Status the
+// controller entrypoint writes, so the two entrypoints never disagree
+// about where a synced commit is recorded. It takes a dynamic.Interface,
+// rather than reaching for a package-level client, so it's unit-testable
+// with a fake.
+func processRepo(ctx context.Context, dynamicClient dynamic.Interface, authResolver *auth.Resolver, wg *sync.WaitGroup, repo ernestoconfig.Repository) {
+	defer wg.Done()
 
-	if err != nil {
-		return "", err
+	repoKey := repo.Namespace + "/" + repo.Name
+	start := time.Now()
+	var processErr error
+	defer func() {
+		metrics.ReconcileDuration.WithLabelValues(repoKey).Observe(time.Since(start).Seconds())
+		if processErr != nil {
+			metrics.ReconcileTotal.WithLabelValues("error").Inc()
+		} else {
+			metrics.ReconcileTotal.WithLabelValues("success").Inc()
+		}
+	}()
+
+	var creds source.Credentials
+	if repo.Auth.Name != "" {
+		var err error
+		creds, err = authResolver.Resolve(ctx, repo.Auth)
+		if err != nil {
+			slog.Error("Failed to resolve credentials", "error", err, "secretRef", repo.Auth.Name)
+			metrics.ReconcileErrors.WithLabelValues(repoKey, "auth").Inc()
+			processErr = err
+			return
+		}
 	}
 
-	ref, err := r.Head()
+	src, err := source.New(repo.Provider, source.Config{URL: repo.URL, Credentials: creds})
 	if err != nil {
-		return "", err
+		slog.Error("unsupported repository provider", "error", err, "provider", repo.Provider)
+		metrics.ReconcileErrors.WithLabelValues(repoKey, "provider").Inc()
+		processErr = err
+		return
 	}
 
-	return ref.Hash().String(), nil
-}
-
-type unstructuredChange struct {
-	keys  []string
-	value string
-}
-
-func (c *client) processRepo(ctx context.Context, wg *sync.WaitGroup, repo Repository) {
-	hash, err := getLatestCommit(ctx, repo)
+	commit, err := src.LatestCommit(ctx, source.Ref{Branch: repo.Branch})
 	if err != nil {
 		slog.Error("Failed to get latest commit",
 			"error", err,
 			"repository", repo.URL)
+		metrics.ReconcileErrors.WithLabelValues(repoKey, "source").Inc()
+		if ernestoerrors.ClassOf(err) == ernestoerrors.Auth {
+			slog.Warn("repository credentials look invalid",
+				"namespace", repo.Namespace,
+				"name", repo.Name)
+		}
+		processErr = err
 		return
 	}
 
 	slog.Info("Latest commit hash retrieved from repository",
 		"repository", repo.Name,
-		"hash", hash)
+		"hash", commit.SHA)
 
-	changeset := []unstructuredChange{
-		{keys: []string{"metadata", "annotations", "ernesto.0x42.in/last-sync-time"}, value: time.Now().Format(time.RFC1123)},
-		{keys: []string{"metadata", "annotations", "ernesto.0x42.in/commit-hash"}, value: hash},
-	}
+	ctx, span := tracer.Start(ctx, "update-retry")
+	defer span.End()
 
-	resource := schema.GroupVersionResource{
-		Group:    "0x42.in",
-		Version:  "v1alpha1",
-		Resource: "githubrepositories",
+	status := ernestoconfig.Status{
+		ObservedCommit: commit.SHA,
+		LastSyncTime:   metav1.Now(),
 	}
 
-	retryErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		result, getErr := c.DynamicClient.Resource(resource).
-			Namespace(repo.Namespace).
-			Get(ctx, repo.Name, metav1.GetOptions{})
-		if getErr != nil {
-			return getErr
-		}
-
-		for _, change := range changeset {
-			if err := unstructured.SetNestedField(result.Object, change.value, change.keys...); err != nil {
-				return err
-			}
-		}
-
-		_, updateErr := c.Resource(resource).Namespace(repo.Namespace).Update(ctx, result, metav1.UpdateOptions{})
-		return updateErr
-	})
-
-	if retryErr != nil {
+	if retryErr := ernestoconfig.PatchStatusDynamic(ctx, dynamicClient, repo, status); retryErr != nil {
+		retryErr = ernestoerrors.Classify(ernestoerrors.Wrap(retryErr, "patch GithubRepository status"), ernestoerrors.Transient)
 		slog.Warn("failed to update GithubRepository",
-			"error", retryErr.Error(),
+			"error", retryErr,
 			"namespace", repo.Namespace,
 			"name", repo.Name,
 		)
+		span.RecordError(retryErr)
+		metrics.ReconcileErrors.WithLabelValues(repoKey, "update").Inc()
+		processErr = retryErr
 	}
-
-	defer wg.Done()
 }
@@ -0,0 +1,63 @@
+// Package auth resolves the credentials referenced by a repository's
+// spec.auth.secretRef into the provider-agnostic source.Credentials the
+// pkg/source bridges expect.
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	ernestoerrors "github.com/AlfredDobradi/go-ernesto/pkg/errors"
+	"github.com/AlfredDobradi/go-ernesto/pkg/source"
+)
+
+// SecretRef points at the corev1.Secret holding a repository's credentials.
+type SecretRef struct {
+	Name      string
+	Namespace string
+}
+
+// Resolver loads credentials referenced by a SecretRef via a typed
+// Kubernetes client.
+type Resolver struct {
+	clientset kubernetes.Interface
+}
+
+// NewResolver builds a Resolver backed by clientset.
+func NewResolver(clientset kubernetes.Interface) *Resolver {
+	return &Resolver{clientset: clientset}
+}
+
+// Resolve fetches the secret named by ref and maps its keys onto
+// source.Credentials. Supported keys: username, password, token,
+// sshPrivateKey, knownHosts.
+func (r *Resolver) Resolve(ctx context.Context, ref SecretRef) (source.Credentials, error) {
+	secret, err := r.clientset.CoreV1().Secrets(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		msg := fmt.Sprintf("auth: failed to get secret %s/%s", ref.Namespace, ref.Name)
+		return source.Credentials{}, ernestoerrors.Classify(ernestoerrors.Wrap(err, msg), ernestoerrors.Auth)
+	}
+
+	return source.Credentials{
+		Username:      string(secret.Data["username"]),
+		Password:      string(secret.Data["password"]),
+		Token:         string(secret.Data["token"]),
+		SSHPrivateKey: secret.Data["sshPrivateKey"],
+		SSHKnownHosts: secret.Data["knownHosts"],
+	}, nil
+}
+
+// ResolveWebhookSecret fetches the shared secret used to validate a
+// provider's webhook signature, stored under the "secret" key of ref.
+func (r *Resolver) ResolveWebhookSecret(ctx context.Context, ref SecretRef) (string, error) {
+	secret, err := r.clientset.CoreV1().Secrets(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		msg := fmt.Sprintf("auth: failed to get secret %s/%s", ref.Namespace, ref.Name)
+		return "", ernestoerrors.Classify(ernestoerrors.Wrap(err, msg), ernestoerrors.Auth)
+	}
+
+	return string(secret.Data["secret"]), nil
+}
@@ -0,0 +1,170 @@
+// Package config holds the GithubRepository custom resource's typed shape
+// and the logic to parse it out of the unstructured object the dynamic
+// client and controller-runtime hand back. It's shared by pkg/controller
+// and cronjob so both entrypoints agree on one definition of a repository.
+package config
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/AlfredDobradi/go-ernesto/pkg/auth"
+)
+
+// GithubRepositoryGVK identifies the custom resource both entrypoints watch.
+var GithubRepositoryGVK = schema.GroupVersionKind{
+	Group:   "0x42.in",
+	Version: "v1alpha1",
+	Kind:    "GithubRepository",
+}
+
+// GithubRepositoriesGVR is GithubRepositoryGVK's resource form, for clients
+// (like cronjob's dynamic.Interface) that address resources by GVR rather
+// than GVK.
+var GithubRepositoriesGVR = schema.GroupVersionResource{
+	Group:    GithubRepositoryGVK.Group,
+	Version:  GithubRepositoryGVK.Version,
+	Resource: "githubrepositories",
+}
+
+// DefaultSyncInterval is the fallback poll interval used for repositories
+// that don't set spec.syncInterval.
+const DefaultSyncInterval = 15 * time.Minute
+
+// DefaultProvider is used for repositories that don't set spec.provider.
+const DefaultProvider = "github"
+
+// Repository is the parsed spec of a GithubRepository custom resource.
+type Repository struct {
+	Name         string
+	Namespace    string
+	URL          string
+	Provider     string
+	Branch       string
+	Auth         auth.SecretRef
+	Webhook      auth.SecretRef
+	SyncInterval time.Duration
+}
+
+// Status mirrors the status subresource written back to the CR after a
+// successful reconcile.
+type Status struct {
+	ObservedCommit string             `json:"observedCommit,omitempty"`
+	LastSyncTime   metav1.Time        `json:"lastSyncTime,omitempty"`
+	WebhookURL     string             `json:"webhookURL,omitempty"`
+	Conditions     []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// FromUnstructured parses obj's spec into a Repository, applying
+// DefaultSyncInterval and DefaultProvider where the corresponding fields
+// are unset.
+func FromUnstructured(obj *unstructured.Unstructured) (Repository, error) {
+	spec, found, err := unstructured.NestedMap(obj.Object, "spec")
+	if err != nil || !found {
+		return Repository{}, fmt.Errorf("spec not found: %w", err)
+	}
+
+	repo := Repository{
+		Name:         obj.GetName(),
+		Namespace:    obj.GetNamespace(),
+		SyncInterval: DefaultSyncInterval,
+		Provider:     DefaultProvider,
+	}
+
+	if url, ok := spec["repoUrl"].(string); ok {
+		repo.URL = url
+	}
+	if provider, ok := spec["provider"].(string); ok {
+		repo.Provider = provider
+	}
+	if branch, ok := spec["branch"].(string); ok {
+		repo.Branch = branch
+	}
+	if secretRef, found, _ := unstructured.NestedMap(spec, "auth", "secretRef"); found {
+		if name, ok := secretRef["name"].(string); ok {
+			repo.Auth.Name = name
+		}
+		repo.Auth.Namespace = repo.Namespace
+		if namespace, ok := secretRef["namespace"].(string); ok && namespace != "" {
+			repo.Auth.Namespace = namespace
+		}
+	}
+	if secretRef, found, _ := unstructured.NestedMap(spec, "webhook", "secretRef"); found {
+		if name, ok := secretRef["name"].(string); ok {
+			repo.Webhook.Name = name
+		}
+		repo.Webhook.Namespace = repo.Namespace
+		if namespace, ok := secretRef["namespace"].(string); ok && namespace != "" {
+			repo.Webhook.Namespace = namespace
+		}
+	}
+	if interval, ok := spec["syncInterval"].(string); ok {
+		if d, err := time.ParseDuration(interval); err == nil {
+			repo.SyncInterval = d
+		}
+	}
+
+	if repo.URL == "" {
+		return Repository{}, fmt.Errorf("spec.repoUrl is required")
+	}
+
+	return repo, nil
+}
+
+// StatusFromUnstructured parses obj's current status subresource into a
+// Status. Callers that need to patch in only a few fields (e.g. appending a
+// Ready condition without touching a previously recorded observedCommit)
+// should read the existing status with this first and amend it, rather than
+// patching from a fresh literal — patchStatus/PatchStatusDynamic replace
+// status wholesale, so an incomplete Status silently drops whatever fields
+// it leaves zero-valued.
+func StatusFromUnstructured(obj *unstructured.Unstructured) (Status, error) {
+	statusMap, found, err := unstructured.NestedMap(obj.Object, "status")
+	if err != nil {
+		return Status{}, err
+	}
+	if !found {
+		return Status{}, nil
+	}
+
+	var status Status
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(statusMap, &status); err != nil {
+		return Status{}, err
+	}
+	return status, nil
+}
+
+// PatchStatusDynamic writes status onto repo's status subresource through a
+// dynamic.Interface, retrying on update conflicts. It's the dynamic-client
+// counterpart of pkg/controller's patchStatus, so cronjob and the
+// controller agree on where a synced commit gets recorded (status, not
+// metadata.annotations) even though they're built on different client
+// stacks.
+func PatchStatusDynamic(ctx context.Context, dynamicClient dynamic.Interface, repo Repository, status Status) error {
+	statusMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&status)
+	if err != nil {
+		return err
+	}
+
+	resource := dynamicClient.Resource(GithubRepositoriesGVR).Namespace(repo.Namespace)
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		obj, err := resource.Get(ctx, repo.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if err := unstructured.SetNestedMap(obj.Object, statusMap, "status"); err != nil {
+			return err
+		}
+		_, err = resource.UpdateStatus(ctx, obj, metav1.UpdateOptions{})
+		return err
+	})
+}
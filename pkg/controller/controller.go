@@ -0,0 +1,366 @@
+// Package controller reconciles GithubRepository custom resources: it
+// resolves each repository's latest commit, via either a webhook-delivered
+// SHA or a pkg/source bridge lookup, and records it on status. It replaces
+// the old global ticker loop — reconciles are triggered by add/update/
+// delete events on the watched resource, by a push event arriving over the
+// webhook receiver, or by the per-object RequeueAfter Reconcile returns,
+// which acts as a fallback poll for repositories whose webhook isn't
+// configured or whose push event was missed.
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/AlfredDobradi/go-ernesto/pkg/auth"
+	"github.com/AlfredDobradi/go-ernesto/pkg/config"
+	ernestoerrors "github.com/AlfredDobradi/go-ernesto/pkg/errors"
+	"github.com/AlfredDobradi/go-ernesto/pkg/metrics"
+	"github.com/AlfredDobradi/go-ernesto/pkg/source"
+	"github.com/AlfredDobradi/go-ernesto/pkg/webhook"
+)
+
+var tracer = otel.Tracer("github.com/AlfredDobradi/go-ernesto/pkg/controller")
+
+// RepositoryReconciler reconciles a single GithubRepository. Construct one
+// with New rather than building the struct directly, so the unexported
+// caches below always start initialized.
+type RepositoryReconciler struct {
+	client.Client
+	Auth           *auth.Resolver
+	Recorder       record.EventRecorder
+	WebhookBaseURL string
+
+	pendingCommits *commitCache
+	webhookEvents  chan event.GenericEvent
+}
+
+// New wires a RepositoryReconciler from its dependencies: the client used
+// to read and patch GithubRepositories, the resolver used to turn a
+// spec.auth.secretRef into source.Credentials, the recorder used to emit
+// Events for Auth-classified errors, and the base URL to publish in
+// status.webhookURL (empty disables that field).
+func New(c client.Client, resolver *auth.Resolver, recorder record.EventRecorder, webhookBaseURL string) *RepositoryReconciler {
+	return &RepositoryReconciler{
+		Client:         c,
+		Auth:           resolver,
+		Recorder:       recorder,
+		WebhookBaseURL: webhookBaseURL,
+		pendingCommits: newCommitCache(),
+		webhookEvents:  make(chan event.GenericEvent, webhookEventBuffer),
+	}
+}
+
+// webhookEventBuffer sizes the channel HandleWebhookEvent enqueues onto, so
+// a short burst of pushes doesn't immediately block the HTTP handler
+// goroutine while the controller is still starting up or catching up.
+const webhookEventBuffer = 16
+
+// webhookEventTimeout bounds how long HandleWebhookEvent will wait for the
+// controller to drain webhookEvents before giving up on an event. Without
+// it, a stalled or not-yet-started controller (source.Channel only drains
+// once mgr.Start runs) would block the handler goroutine, and the
+// provider's HTTP connection, indefinitely.
+const webhookEventTimeout = 5 * time.Second
+
+// WebhookEvents is the channel webhook-triggered reconciles are delivered
+// on. Wire it into the controller with
+// WatchesRawSource(source.Channel(r.WebhookEvents(), ...)).
+func (r *RepositoryReconciler) WebhookEvents() chan event.GenericEvent {
+	return r.webhookEvents
+}
+
+// commitCache holds commit SHAs the webhook receiver has already resolved,
+// keyed by the owning GithubRepository, so Reconcile can patch status
+// without re-cloning or re-querying the provider's API.
+type commitCache struct {
+	mu    sync.Mutex
+	byKey map[types.NamespacedName]string
+}
+
+func newCommitCache() *commitCache {
+	return &commitCache{byKey: map[types.NamespacedName]string{}}
+}
+
+func (c *commitCache) set(key types.NamespacedName, sha string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.byKey[key] = sha
+}
+
+func (c *commitCache) take(key types.NamespacedName) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sha, ok := c.byKey[key]
+	if ok {
+		delete(c.byKey, key)
+	}
+	return sha, ok
+}
+
+func (r *RepositoryReconciler) Reconcile(ctx context.Context, req reconcile.Request) (result reconcile.Result, reconcileErr error) {
+	logger := log.FromContext(ctx)
+	repoKey := req.NamespacedName.String()
+
+	start := time.Now()
+	failed := false
+	defer func() {
+		metrics.ReconcileDuration.WithLabelValues(repoKey).Observe(time.Since(start).Seconds())
+		if reconcileErr != nil || failed {
+			metrics.ReconcileTotal.WithLabelValues("error").Inc()
+		} else {
+			metrics.ReconcileTotal.WithLabelValues("success").Inc()
+		}
+	}()
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(config.GithubRepositoryGVK)
+	if err := r.Get(ctx, req.NamespacedName, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		metrics.ReconcileErrors.WithLabelValues(repoKey, "get").Inc()
+		return reconcile.Result{}, err
+	}
+
+	repo, err := config.FromUnstructured(obj)
+	if err != nil {
+		logger.Error(err, "invalid GithubRepository spec")
+		return reconcile.Result{}, nil
+	}
+
+	var committedAt time.Time
+	hash, fromWebhook := r.pendingCommits.take(req.NamespacedName)
+	if !fromWebhook {
+		var creds source.Credentials
+		if repo.Auth.Name != "" {
+			creds, err = r.Auth.Resolve(ctx, repo.Auth)
+			if err != nil {
+				metrics.ReconcileErrors.WithLabelValues(repoKey, "auth").Inc()
+				failed = true
+				return r.failReconcile(ctx, obj, repo, logger, err, "failed to resolve credentials")
+			}
+		}
+
+		src, err := source.New(repo.Provider, source.Config{URL: repo.URL, Credentials: creds})
+		if err != nil {
+			metrics.ReconcileErrors.WithLabelValues(repoKey, "provider").Inc()
+			failed = true
+			return r.failReconcile(ctx, obj, repo, logger, err, "unsupported repository provider")
+		}
+
+		commit, err := src.LatestCommit(ctx, source.Ref{Branch: repo.Branch})
+		if err != nil {
+			metrics.ReconcileErrors.WithLabelValues(repoKey, "source").Inc()
+			failed = true
+			return r.failReconcile(ctx, obj, repo, logger, err, "failed to get latest commit")
+		}
+		hash = commit.SHA
+		committedAt = commit.CommittedAt
+	}
+
+	logger.Info("latest commit hash retrieved from repository", "repository", repo.Name, "hash", hash, "viaWebhook", fromWebhook)
+
+	if !committedAt.IsZero() {
+		metrics.LatestCommitAge.WithLabelValues(repoKey).Set(time.Since(committedAt).Seconds())
+	}
+
+	status := config.Status{
+		ObservedCommit: hash,
+		LastSyncTime:   metav1.Now(),
+	}
+	if r.WebhookBaseURL != "" {
+		status.WebhookURL = webhook.URL(r.WebhookBaseURL, repo.Provider, repo.Namespace, repo.Name)
+	}
+
+	if err := r.patchStatus(ctx, obj, status); err != nil {
+		metrics.ReconcileErrors.WithLabelValues(repoKey, "patch-status").Inc()
+		failed = true
+		return r.failReconcile(ctx, obj, repo, logger, err, "failed to patch status")
+	}
+
+	return reconcile.Result{RequeueAfter: repo.SyncInterval}, nil
+}
+
+// failReconcile logs err and reacts to its ernestoerrors.Class: Auth errors
+// emit a warning Event on the CR so a human notices the bad credential,
+// Permanent errors set a Ready=False condition and stop requeuing since
+// retrying can't help, and everything else (Transient) is requeued as
+// before. It always returns a nil error: controller-runtime ignores Result
+// entirely whenever Reconcile returns a non-nil error and requeues through
+// its default exponential-backoff rate limiter instead, which would discard
+// repo.SyncInterval on every failure path.
+func (r *RepositoryReconciler) failReconcile(ctx context.Context, obj *unstructured.Unstructured, repo config.Repository, logger logr.Logger, err error, msg string) (reconcile.Result, error) {
+	logger.Error(err, msg)
+
+	switch ernestoerrors.ClassOf(err) {
+	case ernestoerrors.Auth:
+		r.Recorder.Eventf(obj, corev1.EventTypeWarning, "AuthFailed", "%s: %s", msg, err)
+		return reconcile.Result{RequeueAfter: repo.SyncInterval}, nil
+	case ernestoerrors.Permanent:
+		condition := metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionFalse,
+			Reason:             "ReconcileFailed",
+			Message:            err.Error(),
+			LastTransitionTime: metav1.Now(),
+		}
+
+		// Read the status we already recorded rather than patching from a
+		// bare literal: patchStatus replaces status wholesale, so a Status
+		// with only Conditions set would wipe out observedCommit/webhookURL
+		// the moment a repository's error turns Permanent.
+		status, statusErr := config.StatusFromUnstructured(obj)
+		if statusErr != nil {
+			logger.Error(statusErr, "failed to read current status before recording Ready=False condition")
+		}
+		status.Conditions = []metav1.Condition{condition}
+
+		if statusErr := r.patchStatus(ctx, obj, status); statusErr != nil {
+			logger.Error(statusErr, "failed to record Ready=False condition")
+		}
+		return reconcile.Result{}, nil
+	default:
+		return reconcile.Result{RequeueAfter: repo.SyncInterval}, nil
+	}
+}
+
+func (r *RepositoryReconciler) patchStatus(ctx context.Context, obj *unstructured.Unstructured, status config.Status) error {
+	ctx, span := tracer.Start(ctx, "patch-status-retry")
+	defer span.End()
+
+	statusMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&status)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+			return err
+		}
+		if err := unstructured.SetNestedMap(obj.Object, statusMap, "status"); err != nil {
+			return err
+		}
+		return r.Status().Update(ctx, obj)
+	})
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
+
+// MapSecretToRequests re-reconciles every GithubRepository in secretObj's
+// namespace whose spec.auth.secretRef points at it, so rotating a
+// credential takes effect without waiting for the next sync interval.
+func (r *RepositoryReconciler) MapSecretToRequests(ctx context.Context, secretObj client.Object) []reconcile.Request {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   config.GithubRepositoryGVK.Group,
+		Version: config.GithubRepositoryGVK.Version,
+		Kind:    config.GithubRepositoryGVK.Kind + "List",
+	})
+
+	if err := r.List(ctx, list, client.InNamespace(secretObj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list GithubRepositories for secret watch")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range list.Items {
+		item := &list.Items[i]
+		repo, err := config.FromUnstructured(item)
+		if err != nil || repo.Auth.Name != secretObj.GetName() || repo.Auth.Namespace != secretObj.GetNamespace() {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(item)})
+	}
+
+	return requests
+}
+
+// ResolveWebhookSecret backs webhook.Server.Secret: it loads the
+// GithubRepository named by namespace/name and resolves its
+// spec.webhook.secretRef.
+func (r *RepositoryReconciler) ResolveWebhookSecret(ctx context.Context, namespace, name string) (string, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(config.GithubRepositoryGVK)
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, obj); err != nil {
+		return "", err
+	}
+
+	repo, err := config.FromUnstructured(obj)
+	if err != nil {
+		return "", err
+	}
+	if repo.Webhook.Name == "" {
+		return "", fmt.Errorf("spec.webhook.secretRef is not set")
+	}
+
+	return r.Auth.ResolveWebhookSecret(ctx, repo.Webhook)
+}
+
+// HandleWebhookEvent backs webhook.Server.Handler: it records the commit
+// the push event already resolved and enqueues an immediate reconcile for
+// the owning GithubRepository. It gives up after webhookEventTimeout rather
+// than blocking forever, since it runs on the webhook HTTP handler
+// goroutine and a stalled controller shouldn't be able to hang a provider's
+// request indefinitely.
+//
+// Events for a branch other than spec.branch are ignored: otherwise a push
+// to any branch on the repository — a feature branch, a bot's PR branch —
+// would overwrite status.observedCommit even though the branch Reconcile
+// actually tracks never moved. A repository that leaves spec.branch unset
+// has no tracked branch to compare against, so every push is accepted,
+// whichever branch it is on. Reconcile's own "" Ref resolves the source's
+// default branch instead, so for such a repository status.observedCommit
+// can hold a non-default branch's SHA until the next poll.
+func (r *RepositoryReconciler) HandleWebhookEvent(evt webhook.Event) {
+	key := types.NamespacedName{Namespace: evt.Namespace, Name: evt.Name}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(config.GithubRepositoryGVK)
+	if err := r.Get(context.Background(), key, obj); err != nil {
+		log.Log.Error(err, "failed to load GithubRepository for webhook event", "namespace", evt.Namespace, "name", evt.Name)
+		return
+	}
+
+	repo, err := config.FromUnstructured(obj)
+	if err != nil {
+		log.Log.Error(err, "invalid GithubRepository spec for webhook event", "namespace", evt.Namespace, "name", evt.Name)
+		return
+	}
+	if repo.Branch != "" && evt.Branch != repo.Branch {
+		log.Log.Info("ignoring push to untracked branch", "namespace", evt.Namespace, "name", evt.Name, "branch", evt.Branch, "trackedBranch", repo.Branch)
+		return
+	}
+
+	r.pendingCommits.set(key, evt.CommitSHA)
+
+	select {
+	case r.webhookEvents <- event.GenericEvent{Object: obj}:
+	case <-time.After(webhookEventTimeout):
+		err := fmt.Errorf("webhook event channel still full after %s", webhookEventTimeout)
+		log.Log.Error(err, "dropping webhook event", "namespace", evt.Namespace, "name", evt.Name)
+	}
+}
@@ -0,0 +1,188 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/AlfredDobradi/go-ernesto/pkg/config"
+	ernestoerrors "github.com/AlfredDobradi/go-ernesto/pkg/errors"
+	"github.com/AlfredDobradi/go-ernesto/pkg/source"
+)
+
+// fakeSource is a Source whose LatestCommit result is set directly by each
+// test, so Reconcile's success/failure-classification paths can be
+// exercised without talking to a real provider.
+type fakeSource struct {
+	commit source.Commit
+	err    error
+}
+
+func (f *fakeSource) Name() string { return "fake" }
+
+func (f *fakeSource) LatestCommit(context.Context, source.Ref) (source.Commit, error) {
+	return f.commit, f.err
+}
+
+func (f *fakeSource) ListBranches(context.Context) ([]source.Branch, error) {
+	return nil, fmt.Errorf("fakeSource: ListBranches is not supported")
+}
+
+// nextFakeSource is what the "fake" provider factory, registered once below,
+// returns to the next source.New("fake", ...) call.
+var nextFakeSource *fakeSource
+
+func init() {
+	source.Register("fake", func(source.Config) (source.Source, error) {
+		return nextFakeSource, nil
+	})
+}
+
+func newTestScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	listGVK := schema.GroupVersionKind{
+		Group:   config.GithubRepositoryGVK.Group,
+		Version: config.GithubRepositoryGVK.Version,
+		Kind:    config.GithubRepositoryGVK.Kind + "List",
+	}
+	scheme.AddKnownTypeWithName(config.GithubRepositoryGVK, &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(listGVK, &unstructured.UnstructuredList{})
+	return scheme
+}
+
+func newTestRepository(name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(config.GithubRepositoryGVK)
+	obj.SetName(name)
+	obj.SetNamespace("default")
+	_ = unstructured.SetNestedMap(obj.Object, map[string]any{
+		"repoUrl":      "https://example.invalid/o/r.git",
+		"provider":     "fake",
+		"syncInterval": "1m",
+	}, "spec")
+	return obj
+}
+
+func newTestReconciler(objs ...*unstructured.Unstructured) (*RepositoryReconciler, *record.FakeRecorder) {
+	clientObjs := make([]client.Object, len(objs))
+	for i, obj := range objs {
+		clientObjs[i] = obj
+	}
+
+	// GithubRepository has a status subresource, and Reconcile writes through
+	// Status().Update, so the fake client has to know about it too.
+	c := fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(clientObjs...).WithStatusSubresource(clientObjs...).Build()
+	recorder := record.NewFakeRecorder(10)
+
+	return New(c, nil, recorder, ""), recorder
+}
+
+func TestReconcile_Success(t *testing.T) {
+	repo := newTestRepository("repo-a")
+	r, _ := newTestReconciler(repo)
+
+	nextFakeSource = &fakeSource{commit: source.Commit{SHA: "deadbeef", CommittedAt: time.Now()}}
+
+	result, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "repo-a"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RequeueAfter != time.Minute {
+		t.Errorf("expected RequeueAfter to be the repo's syncInterval (1m), got %s", result.RequeueAfter)
+	}
+
+	updated := &unstructured.Unstructured{}
+	updated.SetGroupVersionKind(config.GithubRepositoryGVK)
+	if err := r.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "repo-a"}, updated); err != nil {
+		t.Fatalf("failed to fetch updated object: %v", err)
+	}
+
+	observed, found, err := unstructured.NestedString(updated.Object, "status", "observedCommit")
+	if err != nil || !found || observed != "deadbeef" {
+		t.Errorf("expected status.observedCommit = %q, got %q (found=%v, err=%v)", "deadbeef", observed, found, err)
+	}
+}
+
+func TestReconcile_TransientError_RequeuesWithoutError(t *testing.T) {
+	repo := newTestRepository("repo-b")
+	r, _ := newTestReconciler(repo)
+
+	nextFakeSource = &fakeSource{err: ernestoerrors.Classify(fmt.Errorf("boom"), ernestoerrors.Transient)}
+
+	result, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "repo-b"}})
+	if err != nil {
+		t.Fatalf("expected a Transient error to return a nil error so RequeueAfter isn't discarded, got %v", err)
+	}
+	if result.RequeueAfter != time.Minute {
+		t.Errorf("expected RequeueAfter to be the repo's syncInterval (1m) on a Transient failure, got %s", result.RequeueAfter)
+	}
+}
+
+func TestReconcile_AuthError_EmitsEventAndRequeues(t *testing.T) {
+	repo := newTestRepository("repo-c")
+	r, recorder := newTestReconciler(repo)
+
+	nextFakeSource = &fakeSource{err: ernestoerrors.Classify(fmt.Errorf("bad credentials"), ernestoerrors.Auth)}
+
+	result, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "repo-c"}})
+	if err != nil {
+		t.Fatalf("expected an Auth error to return a nil error, got %v", err)
+	}
+	if result.RequeueAfter != time.Minute {
+		t.Errorf("expected an Auth failure to still requeue after the repo's syncInterval, got %s", result.RequeueAfter)
+	}
+
+	select {
+	case event := <-recorder.Events:
+		if !containsWarning(event) {
+			t.Errorf("expected a Warning event for an Auth failure, got %q", event)
+		}
+	default:
+		t.Error("expected an Event to be recorded for an Auth failure, got none")
+	}
+}
+
+func TestReconcile_PermanentError_StopsRequeueAndSetsReadyFalse(t *testing.T) {
+	repo := newTestRepository("repo-d")
+	r, _ := newTestReconciler(repo)
+
+	nextFakeSource = &fakeSource{err: ernestoerrors.Classify(fmt.Errorf("repository moved"), ernestoerrors.Permanent)}
+
+	result, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "repo-d"}})
+	if err != nil {
+		t.Fatalf("expected a Permanent error to return a nil error, got %v", err)
+	}
+	if result.RequeueAfter != 0 {
+		t.Errorf("expected a Permanent failure to stop requeuing, got RequeueAfter=%s", result.RequeueAfter)
+	}
+
+	updated := &unstructured.Unstructured{}
+	updated.SetGroupVersionKind(config.GithubRepositoryGVK)
+	if err := r.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "repo-d"}, updated); err != nil {
+		t.Fatalf("failed to fetch updated object: %v", err)
+	}
+
+	conditions, found, err := unstructured.NestedSlice(updated.Object, "status", "conditions")
+	if err != nil || !found || len(conditions) != 1 {
+		t.Fatalf("expected exactly one status condition, found=%v err=%v conditions=%v", found, err, conditions)
+	}
+	condition, ok := conditions[0].(map[string]any)
+	if !ok || condition["type"] != "Ready" || condition["status"] != string(metav1.ConditionFalse) {
+		t.Errorf("expected a Ready=False condition, got %v", condition)
+	}
+}
+
+func containsWarning(event string) bool {
+	return len(event) > 0 && event[0] == 'W'
+}
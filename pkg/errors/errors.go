@@ -0,0 +1,125 @@
+// Package errors provides stack-preserving error wrapping and
+// classification for ernesto's controllers. Wrap and WithStack record the
+// caller's file:line as they bubble up, and implement slog.LogValuer so
+// logging one emits a compact "file:line -> file:line" chain instead of a
+// full stack trace. Classify tags an error with a Class a caller can act on:
+// retry with backoff, give up and mark the resource not-Ready, or surface a
+// Kubernetes Event for a human to fix.
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+)
+
+// Class categorizes how a caller should react to an error.
+type Class int
+
+const (
+	// Transient errors are expected to clear up on their own; retry with backoff.
+	Transient Class = iota
+	// Permanent errors won't clear up by retrying; set Ready=False and stop requeuing.
+	Permanent
+	// Auth errors indicate bad or missing credentials; emit a Kubernetes Event on the CR.
+	Auth
+)
+
+func (c Class) String() string {
+	switch c {
+	case Permanent:
+		return "Permanent"
+	case Auth:
+		return "Auth"
+	default:
+		return "Transient"
+	}
+}
+
+// wrapped decorates an error with the call site that wrapped it and,
+// optionally, a Class.
+type wrapped struct {
+	msg      string
+	err      error
+	class    Class
+	hasClass bool
+	file     string
+	line     int
+}
+
+func (w *wrapped) Error() string {
+	if w.msg == "" {
+		return w.err.Error()
+	}
+	return fmt.Sprintf("%s: %s", w.msg, w.err.Error())
+}
+
+func (w *wrapped) Unwrap() error { return w.err }
+
+// LogValue implements slog.LogValuer, emitting the error alongside a
+// compact file:line chain gathered from every wrapped frame.
+func (w *wrapped) LogValue() slog.Value {
+	var chain []string
+	for cur := error(w); cur != nil; cur = errors.Unwrap(cur) {
+		wr, ok := cur.(*wrapped)
+		if !ok {
+			continue
+		}
+		chain = append(chain, fmt.Sprintf("%s:%d", wr.file, wr.line))
+	}
+
+	return slog.GroupValue(
+		slog.String("error", w.Error()),
+		slog.String("class", ClassOf(w).String()),
+		slog.String("chain", strings.Join(chain, " -> ")),
+	)
+}
+
+// callerFrame reports the file:line of Wrap/WithStack/Classify's caller.
+func callerFrame() (file string, line int) {
+	_, file, line, ok := runtime.Caller(2)
+	if !ok {
+		return "unknown", 0
+	}
+	return file, line
+}
+
+// Wrap annotates err with msg and the caller's file:line. Returns nil if err is nil.
+func Wrap(err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+	file, line := callerFrame()
+	return &wrapped{msg: msg, err: err, file: file, line: line}
+}
+
+// WithStack annotates err with the caller's file:line but no extra message.
+func WithStack(err error) error {
+	if err == nil {
+		return nil
+	}
+	file, line := callerFrame()
+	return &wrapped{err: err, file: file, line: line}
+}
+
+// Classify wraps err and tags it with class so ClassOf can recover it later.
+func Classify(err error, class Class) error {
+	if err == nil {
+		return nil
+	}
+	file, line := callerFrame()
+	return &wrapped{err: err, class: class, hasClass: true, file: file, line: line}
+}
+
+// ClassOf walks err's chain for the nearest Classify call, defaulting to Transient.
+func ClassOf(err error) Class {
+	for err != nil {
+		if wr, ok := err.(*wrapped); ok && wr.hasClass {
+			return wr.class
+		}
+		err = errors.Unwrap(err)
+	}
+	return Transient
+}
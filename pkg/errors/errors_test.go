@@ -0,0 +1,103 @@
+package errors
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWrapNil(t *testing.T) {
+	if Wrap(nil, "msg") != nil {
+		t.Error("expected Wrap(nil, ...) to return nil")
+	}
+}
+
+func TestWrapMessageAndUnwrap(t *testing.T) {
+	base := errors.New("boom")
+	err := Wrap(base, "doing thing")
+
+	if got, want := err.Error(), "doing thing: boom"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if !errors.Is(err, base) {
+		t.Error("expected Wrap to preserve Unwrap to the original error")
+	}
+}
+
+func TestWithStackNil(t *testing.T) {
+	if WithStack(nil) != nil {
+		t.Error("expected WithStack(nil) to return nil")
+	}
+}
+
+func TestWithStackNoMessage(t *testing.T) {
+	base := errors.New("boom")
+	err := WithStack(base)
+	if got, want := err.Error(), "boom"; got != want {
+		t.Errorf("Error() = %q, want %q (WithStack shouldn't add a message)", got, want)
+	}
+}
+
+func TestClassifyAndClassOf(t *testing.T) {
+	cases := []struct {
+		name  string
+		class Class
+	}{
+		{"transient", Transient},
+		{"permanent", Permanent},
+		{"auth", Auth},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Classify(errors.New("boom"), tc.class)
+			if got := ClassOf(err); got != tc.class {
+				t.Errorf("ClassOf() = %v, want %v", got, tc.class)
+			}
+		})
+	}
+}
+
+func TestClassifyNil(t *testing.T) {
+	if Classify(nil, Permanent) != nil {
+		t.Error("expected Classify(nil, ...) to return nil")
+	}
+}
+
+func TestClassOfUnclassifiedDefaultsTransient(t *testing.T) {
+	if got := ClassOf(errors.New("boom")); got != Transient {
+		t.Errorf("ClassOf() on a plain error = %v, want Transient", got)
+	}
+}
+
+func TestClassOfWalksWrappedChain(t *testing.T) {
+	err := Wrap(Classify(errors.New("boom"), Auth), "resolving credentials")
+	if got := ClassOf(err); got != Auth {
+		t.Errorf("ClassOf() = %v, want Auth to survive a later Wrap", got)
+	}
+}
+
+func TestLogValueIncludesChain(t *testing.T) {
+	err := Wrap(WithStack(errors.New("boom")), "outer")
+
+	logged, ok := err.(*wrapped)
+	if !ok {
+		t.Fatal("expected *wrapped")
+	}
+
+	attrs := logged.LogValue().Group()
+	byKey := map[string]string{}
+	for _, a := range attrs {
+		byKey[a.Key] = a.Value.String()
+	}
+
+	if byKey["error"] != "outer: boom" {
+		t.Errorf(`attrs["error"] = %q, want "outer: boom"`, byKey["error"])
+	}
+	if byKey["class"] != "Transient" {
+		t.Errorf(`attrs["class"] = %q, want "Transient"`, byKey["class"])
+	}
+	if !strings.Contains(byKey["chain"], "->") {
+		t.Errorf(`attrs["chain"] = %q, want both wrapped frames joined by "->"`, byKey["chain"])
+	}
+}
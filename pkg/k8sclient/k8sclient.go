@@ -0,0 +1,39 @@
+// Package k8sclient builds the *rest.Config both entrypoints start from,
+// so that config resolution lives in one place instead of each main()
+// reaching for rest.InClusterConfig directly.
+package k8sclient
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// RestConfig resolves a *rest.Config for the cluster ernesto should talk
+// to. It tries in-cluster config first, since that's how ernesto normally
+// runs, and falls back to KUBECONFIG (or $HOME/.kube/config) so it can also
+// run out-of-cluster during local development.
+func RestConfig() (*rest.Config, error) {
+	if cfg, err := rest.InClusterConfig(); err == nil {
+		return cfg, nil
+	}
+
+	kubeconfig := os.Getenv("KUBECONFIG")
+	if kubeconfig == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("k8sclient: not running in-cluster and KUBECONFIG is unset: %w", err)
+		}
+		kubeconfig = filepath.Join(home, ".kube", "config")
+	}
+
+	cfg, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("k8sclient: failed to load kubeconfig %s: %w", kubeconfig, err)
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,65 @@
+// Package metrics registers the Prometheus collectors Ernesto exposes and
+// serves them alongside the health/readiness endpoints.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	// ReconcileTotal counts reconciles, partitioned by result ("success" or "error").
+	ReconcileTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ernesto_reconcile_total",
+		Help: "Total number of reconciles, partitioned by result.",
+	}, []string{"result"})
+
+	// ReconcileDuration tracks how long a single reconcile took, per repository.
+	ReconcileDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "ernesto_reconcile_duration_seconds",
+		Help:    "Duration of a single reconcile, partitioned by repository.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"repo"})
+
+	// GitCloneDuration tracks how long the generic git fallback bridge spent cloning, per provider.
+	GitCloneDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "ernesto_git_clone_duration_seconds",
+		Help:    "Duration of cloning a repository to read its HEAD, partitioned by provider.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"provider"})
+
+	// LatestCommitAge tracks how old the most recently observed commit is, per repository.
+	LatestCommitAge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ernesto_latest_commit_age_seconds",
+		Help: "Age of the most recently observed commit, partitioned by repository.",
+	}, []string{"repo"})
+
+	// ReconcileErrors counts reconcile failures, partitioned by repository and the stage that failed.
+	ReconcileErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ernesto_reconcile_errors_total",
+		Help: "Total number of reconcile errors, partitioned by repository and stage.",
+	}, []string{"repo", "stage"})
+)
+
+// Server serves /metrics, /healthz, and /readyz on Addr.
+type Server struct {
+	Addr string
+}
+
+// ListenAndServe starts the metrics HTTP server. It blocks until the server
+// stops or returns an error.
+func (s *Server) ListenAndServe() error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/healthz", healthz)
+	mux.HandleFunc("/readyz", healthz)
+
+	return (&http.Server{Addr: s.Addr, Handler: mux}).ListenAndServe()
+}
+
+func healthz(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
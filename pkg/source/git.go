@@ -0,0 +1,139 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/AlfredDobradi/go-ernesto/pkg/metrics"
+)
+
+var tracer = otel.Tracer("github.com/AlfredDobradi/go-ernesto/pkg/source")
+
+func init() {
+	Register("git", newGit)
+}
+
+// gitSource is the generic fallback bridge: it shallow-clones the
+// repository into memory to read its HEAD. It's only used when the provider
+// name is "git" (e.g. for a host without a dedicated bridge); New does not
+// fall back to it for an unknown provider or for a URL another bridge can't
+// parse.
+type gitSource struct {
+	cfg Config
+}
+
+func newGit(cfg Config) (Source, error) {
+	return &gitSource{cfg: cfg}, nil
+}
+
+func (g *gitSource) Name() string { return "git" }
+
+func (g *gitSource) LatestCommit(ctx context.Context, ref Ref) (Commit, error) {
+	ctx, span := tracer.Start(ctx, "source.git.clone", trace.WithAttributes(attribute.String("url", g.cfg.URL)))
+	defer span.End()
+
+	auth, err := buildAuth(g.cfg.Credentials)
+	if err != nil {
+		span.RecordError(err)
+		return Commit{}, err
+	}
+
+	opts := &git.CloneOptions{URL: g.cfg.URL, Auth: auth, Depth: 1}
+	if ref.Branch != "" {
+		opts.ReferenceName = plumbing.NewBranchReferenceName(ref.Branch)
+	}
+
+	start := time.Now()
+	r, err := git.CloneContext(ctx, memory.NewStorage(), nil, opts)
+	metrics.GitCloneDuration.WithLabelValues("git").Observe(time.Since(start).Seconds())
+	if err != nil {
+		span.RecordError(err)
+		return Commit{}, err
+	}
+
+	head, err := r.Head()
+	if err != nil {
+		span.RecordError(err)
+		return Commit{}, err
+	}
+
+	commit, err := r.CommitObject(head.Hash())
+	if err != nil {
+		span.RecordError(err)
+		return Commit{SHA: head.Hash().String()}, nil
+	}
+
+	return Commit{SHA: head.Hash().String(), CommittedAt: commit.Committer.When}, nil
+}
+
+func (g *gitSource) ListBranches(context.Context) ([]Branch, error) {
+	return nil, fmt.Errorf("git: ListBranches is not supported by the generic fallback bridge")
+}
+
+// buildAuth picks the go-git transport.AuthMethod matching whichever
+// credential fields are set on creds, preferring an SSH key over a token
+// over basic auth. It returns a nil AuthMethod (no error) for an anonymous
+// repository.
+func buildAuth(creds Credentials) (transport.AuthMethod, error) {
+	switch {
+	case len(creds.SSHPrivateKey) > 0:
+		keys, err := ssh.NewPublicKeys("git", creds.SSHPrivateKey, "")
+		if err != nil {
+			return nil, fmt.Errorf("source: failed to parse ssh private key: %w", err)
+		}
+
+		if len(creds.SSHKnownHosts) > 0 {
+			callback, err := knownHostsCallback(creds.SSHKnownHosts)
+			if err != nil {
+				return nil, err
+			}
+			keys.HostKeyCallback = callback
+		}
+
+		return keys, nil
+
+	case creds.Token != "":
+		return &http.TokenAuth{Token: creds.Token}, nil
+
+	case creds.Username != "" || creds.Password != "":
+		return &http.BasicAuth{Username: creds.Username, Password: creds.Password}, nil
+
+	default:
+		return nil, nil
+	}
+}
+
+func knownHostsCallback(knownHosts []byte) (ssh.HostKeyCallback, error) {
+	f, err := os.CreateTemp("", "ernesto-known-hosts-*")
+	if err != nil {
+		return nil, fmt.Errorf("source: failed to stage known_hosts: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(knownHosts); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("source: failed to stage known_hosts: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("source: failed to stage known_hosts: %w", err)
+	}
+
+	callback, err := ssh.NewKnownHostsCallback(f.Name())
+	if err != nil {
+		return nil, fmt.Errorf("source: failed to parse known_hosts: %w", err)
+	}
+
+	return callback, nil
+}
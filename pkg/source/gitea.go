@@ -0,0 +1,111 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+func init() {
+	Register("gitea", newGitea)
+}
+
+// gitea talks to the Gitea REST API directly, so resolving a branch's
+// latest commit doesn't require cloning the repository. Gitea instances are
+// self-hosted, so cfg.URL's own host is used as the API base rather than a
+// fixed domain.
+type gitea struct {
+	cfg         Config
+	baseURL     string
+	owner, repo string
+}
+
+func newGitea(cfg Config) (Source, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("source: %q is not a valid gitea URL: %w", cfg.URL, err)
+	}
+
+	owner, repo, err := ownerRepoFromURL(cfg.URL, u.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gitea{
+		cfg:     cfg,
+		baseURL: u.Scheme + "://" + u.Host,
+		owner:   owner,
+		repo:    repo,
+	}, nil
+}
+
+func (g *gitea) Name() string { return "gitea" }
+
+func (g *gitea) LatestCommit(ctx context.Context, ref Ref) (Commit, error) {
+	branch := ref.Branch
+	if branch == "" {
+		branch = "HEAD"
+	}
+
+	var payload struct {
+		SHA    string `json:"sha"`
+		Commit struct {
+			Committer struct {
+				Date time.Time `json:"date"`
+			} `json:"committer"`
+		} `json:"commit"`
+	}
+	apiURL := fmt.Sprintf("%s/api/v1/repos/%s/%s/commits/%s", g.baseURL, g.owner, g.repo, branch)
+	if err := g.getJSON(ctx, apiURL, &payload); err != nil {
+		return Commit{}, err
+	}
+
+	return Commit{SHA: payload.SHA, CommittedAt: payload.Commit.Committer.Date}, nil
+}
+
+func (g *gitea) ListBranches(ctx context.Context) ([]Branch, error) {
+	var payload []struct {
+		Name   string `json:"name"`
+		Commit struct {
+			ID string `json:"id"`
+		} `json:"commit"`
+	}
+	apiURL := fmt.Sprintf("%s/api/v1/repos/%s/%s/branches", g.baseURL, g.owner, g.repo)
+	if err := g.getJSON(ctx, apiURL, &payload); err != nil {
+		return nil, err
+	}
+
+	branches := make([]Branch, len(payload))
+	for i, b := range payload {
+		branches[i] = Branch{Name: b.Name, SHA: b.Commit.ID}
+	}
+	return branches, nil
+}
+
+func (g *gitea) getJSON(ctx context.Context, apiURL string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return err
+	}
+	switch {
+	case g.cfg.Credentials.Token != "":
+		req.Header.Set("Authorization", "token "+g.cfg.Credentials.Token)
+	case g.cfg.Credentials.Username != "" || g.cfg.Credentials.Password != "":
+		req.SetBasicAuth(g.cfg.Credentials.Username, g.cfg.Credentials.Password)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return unexpectedStatusErr("gitea", resp, apiURL)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,103 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+func init() {
+	Register("github", newGitHub)
+}
+
+// githubAPIBase is GitHub's REST API host. gitHub copies it into its apiBase
+// field, rather than LatestCommit/ListBranches using it as a literal, only
+// so tests can point a gitHub at an httptest.Server instead of the real API.
+const githubAPIBase = "https://api.github.com"
+
+// gitHub talks to the GitHub REST API directly, so resolving a branch's
+// latest commit doesn't require cloning the repository.
+type gitHub struct {
+	cfg         Config
+	apiBase     string
+	owner, repo string
+}
+
+func newGitHub(cfg Config) (Source, error) {
+	owner, repo, err := ownerRepoFromURL(cfg.URL, "github.com")
+	if err != nil {
+		return nil, err
+	}
+	return &gitHub{cfg: cfg, apiBase: githubAPIBase, owner: owner, repo: repo}, nil
+}
+
+func (g *gitHub) Name() string { return "github" }
+
+func (g *gitHub) LatestCommit(ctx context.Context, ref Ref) (Commit, error) {
+	branch := ref.Branch
+	if branch == "" {
+		branch = "HEAD"
+	}
+
+	var payload struct {
+		SHA    string `json:"sha"`
+		Commit struct {
+			Committer struct {
+				Date time.Time `json:"date"`
+			} `json:"committer"`
+		} `json:"commit"`
+	}
+	url := fmt.Sprintf("%s/repos/%s/%s/commits/%s", g.apiBase, g.owner, g.repo, branch)
+	if err := g.getJSON(ctx, url, &payload); err != nil {
+		return Commit{}, err
+	}
+
+	return Commit{SHA: payload.SHA, CommittedAt: payload.Commit.Committer.Date}, nil
+}
+
+func (g *gitHub) ListBranches(ctx context.Context) ([]Branch, error) {
+	var payload []struct {
+		Name   string `json:"name"`
+		Commit struct {
+			SHA string `json:"sha"`
+		} `json:"commit"`
+	}
+	url := fmt.Sprintf("%s/repos/%s/%s/branches", g.apiBase, g.owner, g.repo)
+	if err := g.getJSON(ctx, url, &payload); err != nil {
+		return nil, err
+	}
+
+	branches := make([]Branch, len(payload))
+	for i, b := range payload {
+		branches[i] = Branch{Name: b.Name, SHA: b.Commit.SHA}
+	}
+	return branches, nil
+}
+
+func (g *gitHub) getJSON(ctx context.Context, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	switch {
+	case g.cfg.Credentials.Token != "":
+		req.Header.Set("Authorization", "Bearer "+g.cfg.Credentials.Token)
+	case g.cfg.Credentials.Username != "" || g.cfg.Credentials.Password != "":
+		req.SetBasicAuth(g.cfg.Credentials.Username, g.cfg.Credentials.Password)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return unexpectedStatusErr("github", resp, url)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
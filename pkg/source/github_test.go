@@ -0,0 +1,92 @@
+package source
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ernestoerrors "github.com/AlfredDobradi/go-ernesto/pkg/errors"
+)
+
+func TestGitHubLatestCommitDefaultsToHEAD(t *testing.T) {
+	var gotPath, gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		w.Write([]byte(`{"sha":"deadbeef","commit":{"committer":{"date":"2024-01-01T00:00:00Z"}}}`))
+	}))
+	defer server.Close()
+
+	g := &gitHub{cfg: Config{Credentials: Credentials{Token: "tok"}}, apiBase: server.URL, owner: "foo", repo: "bar"}
+
+	commit, err := g.LatestCommit(context.Background(), Ref{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if commit.SHA != "deadbeef" {
+		t.Errorf("SHA = %q, want %q", commit.SHA, "deadbeef")
+	}
+	if want := "/repos/foo/bar/commits/HEAD"; gotPath != want {
+		t.Errorf("request path = %q, want %q (empty Ref.Branch should resolve HEAD)", gotPath, want)
+	}
+	if want := "Bearer tok"; gotAuth != want {
+		t.Errorf("Authorization header = %q, want %q", gotAuth, want)
+	}
+}
+
+func TestGitHubLatestCommitHonorsRefBranch(t *testing.T) {
+	var gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.Write([]byte(`{"sha":"cafef00d"}`))
+	}))
+	defer server.Close()
+
+	g := &gitHub{cfg: Config{}, apiBase: server.URL, owner: "foo", repo: "bar"}
+
+	if _, err := g.LatestCommit(context.Background(), Ref{Branch: "develop"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "/repos/foo/bar/commits/develop"; gotPath != want {
+		t.Errorf("request path = %q, want %q", gotPath, want)
+	}
+}
+
+func TestGitHubGetJSONBasicAuthFallback(t *testing.T) {
+	var gotUser, gotPass string
+	var gotOK bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser, gotPass, gotOK = r.BasicAuth()
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	g := &gitHub{cfg: Config{Credentials: Credentials{Username: "alice", Password: "s3cr3t"}}, apiBase: server.URL, owner: "foo", repo: "bar"}
+
+	var out map[string]any
+	if err := g.getJSON(context.Background(), server.URL+"/repos/foo/bar/commits/HEAD", &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !gotOK || gotUser != "alice" || gotPass != "s3cr3t" {
+		t.Errorf("got basic auth (%q, %q, ok=%v), want (alice, s3cr3t, true)", gotUser, gotPass, gotOK)
+	}
+}
+
+func TestGitHubGetJSONUnexpectedStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	g := &gitHub{cfg: Config{}, apiBase: server.URL, owner: "foo", repo: "bar"}
+
+	var out map[string]any
+	err := g.getJSON(context.Background(), server.URL, &out)
+	if err == nil {
+		t.Fatal("expected an error for a 401 response")
+	}
+	if ernestoerrors.ClassOf(err) != ernestoerrors.Auth {
+		t.Errorf("ClassOf(err) = %v, want Auth for a 401 response", ernestoerrors.ClassOf(err))
+	}
+}
@@ -0,0 +1,94 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+func init() {
+	Register("gitlab", newGitLab)
+}
+
+// gitLab talks to the GitLab REST API directly, so resolving a branch's
+// latest commit doesn't require cloning the repository.
+type gitLab struct {
+	cfg         Config
+	projectPath string
+}
+
+func newGitLab(cfg Config) (Source, error) {
+	owner, repo, err := ownerRepoFromURL(cfg.URL, "gitlab.com")
+	if err != nil {
+		return nil, err
+	}
+	return &gitLab{cfg: cfg, projectPath: owner + "/" + repo}, nil
+}
+
+func (g *gitLab) Name() string { return "gitlab" }
+
+func (g *gitLab) LatestCommit(ctx context.Context, ref Ref) (Commit, error) {
+	branch := ref.Branch
+	if branch == "" {
+		branch = "HEAD"
+	}
+
+	var payload struct {
+		ID            string    `json:"id"`
+		CommittedDate time.Time `json:"committed_date"`
+	}
+	apiURL := fmt.Sprintf("https://gitlab.com/api/v4/projects/%s/repository/commits/%s",
+		url.PathEscape(g.projectPath), url.PathEscape(branch))
+	if err := g.getJSON(ctx, apiURL, &payload); err != nil {
+		return Commit{}, err
+	}
+
+	return Commit{SHA: payload.ID, CommittedAt: payload.CommittedDate}, nil
+}
+
+func (g *gitLab) ListBranches(ctx context.Context) ([]Branch, error) {
+	var payload []struct {
+		Name   string `json:"name"`
+		Commit struct {
+			ID string `json:"id"`
+		} `json:"commit"`
+	}
+	apiURL := fmt.Sprintf("https://gitlab.com/api/v4/projects/%s/repository/branches", url.PathEscape(g.projectPath))
+	if err := g.getJSON(ctx, apiURL, &payload); err != nil {
+		return nil, err
+	}
+
+	branches := make([]Branch, len(payload))
+	for i, b := range payload {
+		branches[i] = Branch{Name: b.Name, SHA: b.Commit.ID}
+	}
+	return branches, nil
+}
+
+func (g *gitLab) getJSON(ctx context.Context, apiURL string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return err
+	}
+	switch {
+	case g.cfg.Credentials.Token != "":
+		req.Header.Set("PRIVATE-TOKEN", g.cfg.Credentials.Token)
+	case g.cfg.Credentials.Username != "" || g.cfg.Credentials.Password != "":
+		req.SetBasicAuth(g.cfg.Credentials.Username, g.cfg.Credentials.Password)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return unexpectedStatusErr("gitlab", resp, apiURL)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,26 @@
+package source
+
+import (
+	"fmt"
+	"net/http"
+
+	ernestoerrors "github.com/AlfredDobradi/go-ernesto/pkg/errors"
+)
+
+// classifyStatus classifies a non-200 REST API response: 401/403 mean the
+// credential is bad or missing, so callers should surface it via a
+// Kubernetes Event rather than retry forever; anything else is treated as
+// Transient.
+func classifyStatus(status int) ernestoerrors.Class {
+	if status == http.StatusUnauthorized || status == http.StatusForbidden {
+		return ernestoerrors.Auth
+	}
+	return ernestoerrors.Transient
+}
+
+// unexpectedStatusErr builds a classified error for a non-200 response from
+// one of the REST API bridges.
+func unexpectedStatusErr(provider string, resp *http.Response, url string) error {
+	err := fmt.Errorf("%s: unexpected status %s for %s", provider, resp.Status, url)
+	return ernestoerrors.Classify(err, classifyStatus(resp.StatusCode))
+}
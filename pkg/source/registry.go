@@ -0,0 +1,36 @@
+package source
+
+import (
+	"fmt"
+
+	ernestoerrors "github.com/AlfredDobradi/go-ernesto/pkg/errors"
+)
+
+// Config carries the per-repository parameters needed to construct a Source.
+type Config struct {
+	URL         string
+	Credentials Credentials
+}
+
+// Factory builds a Source bound to a single repository. Bridges call
+// Register with their own Factory from an init() function.
+type Factory func(cfg Config) (Source, error)
+
+var registry = map[string]Factory{}
+
+// Register adds a provider factory under name, e.g. "github". It is meant
+// to be called from each bridge's init().
+func Register(name string, factory Factory) {
+	registry[name] = factory
+}
+
+// New builds the Source for provider, returning an error if no bridge is
+// registered under that name.
+func New(provider string, cfg Config) (Source, error) {
+	factory, ok := registry[provider]
+	if !ok {
+		err := fmt.Errorf("source: unknown provider %q", provider)
+		return nil, ernestoerrors.Classify(err, ernestoerrors.Permanent)
+	}
+	return factory(cfg)
+}
@@ -0,0 +1,56 @@
+// Package source defines the pluggable bridge used to resolve the latest
+// commit of a repository hosted on a particular Git provider. Each provider
+// (github, gitlab, gitea, or the generic git fallback) registers a Factory
+// under its own name; callers look up a Source through the registry rather
+// than importing a concrete bridge directly.
+package source
+
+import (
+	"context"
+	"time"
+)
+
+// Ref identifies the branch to resolve. An empty Ref resolves the
+// repository's default branch.
+type Ref struct {
+	Branch string
+}
+
+// Commit is the result of resolving a Ref against a Source.
+type Commit struct {
+	SHA string
+	// CommittedAt is the commit's committer timestamp. It is the zero Time
+	// when a bridge can't report it.
+	CommittedAt time.Time
+}
+
+// Branch describes a single branch as reported by a Source.
+type Branch struct {
+	Name string
+	SHA  string
+}
+
+// Credentials carries the resolved authentication material for a single
+// repository, regardless of which provider it targets. A bridge uses
+// whichever fields are relevant to it and ignores the rest: the REST API
+// bridges (github/gitlab/gitea) authenticate plain HTTP requests, so only
+// Token and Username/Password apply to them; SSHPrivateKey/SSHKnownHosts
+// only apply to the generic git-clone fallback bridge.
+type Credentials struct {
+	Username      string
+	Password      string
+	Token         string
+	SSHPrivateKey []byte
+	SSHKnownHosts []byte
+}
+
+// Source is a bridge to a single Git hosting provider, bound to one
+// repository. Implementations must be safe for concurrent use.
+type Source interface {
+	// Name returns the provider's discriminator value, e.g. "github".
+	Name() string
+	// LatestCommit resolves ref against the bound repository.
+	LatestCommit(ctx context.Context, ref Ref) (Commit, error)
+	// ListBranches lists the branches known to the bound repository.
+	ListBranches(ctx context.Context) ([]Branch, error)
+}
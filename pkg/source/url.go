@@ -0,0 +1,25 @@
+package source
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ownerRepoFromURL extracts the "owner/repo" path segments from a repository
+// URL hosted on host, e.g. "https://github.com/foo/bar.git" -> ("foo", "bar").
+func ownerRepoFromURL(rawURL, host string) (owner, repo string, err error) {
+	trimmed := strings.TrimSuffix(rawURL, ".git")
+
+	idx := strings.Index(trimmed, host+"/")
+	if idx == -1 {
+		return "", "", fmt.Errorf("source: %q is not a %s URL", rawURL, host)
+	}
+
+	path := trimmed[idx+len(host)+1:]
+	parts := strings.SplitN(path, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("source: could not parse owner/repo from %q", rawURL)
+	}
+
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,46 @@
+package source
+
+import "testing"
+
+func TestOwnerRepoFromURL(t *testing.T) {
+	cases := []struct {
+		name      string
+		url, host string
+		owner     string
+		repo      string
+	}{
+		{"https with .git suffix", "https://github.com/foo/bar.git", "github.com", "foo", "bar"},
+		{"https without .git suffix", "https://github.com/foo/bar", "github.com", "foo", "bar"},
+		{"self-hosted host", "https://git.example.com/foo/bar.git", "git.example.com", "foo", "bar"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			owner, repo, err := ownerRepoFromURL(tc.url, tc.host)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if owner != tc.owner || repo != tc.repo {
+				t.Errorf("ownerRepoFromURL(%q, %q) = (%q, %q), want (%q, %q)", tc.url, tc.host, owner, repo, tc.owner, tc.repo)
+			}
+		})
+	}
+}
+
+func TestOwnerRepoFromURLErrors(t *testing.T) {
+	cases := []struct {
+		name      string
+		url, host string
+	}{
+		{"wrong host", "https://gitlab.com/foo/bar.git", "github.com"},
+		{"missing repo segment", "https://github.com/foo", "github.com"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, _, err := ownerRepoFromURL(tc.url, tc.host); err == nil {
+				t.Errorf("ownerRepoFromURL(%q, %q) = nil error, want one", tc.url, tc.host)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+func verifyGitHubSignature(secret string, body []byte, r *http.Request) error {
+	return verifyHMACHeader(secret, body, r, "X-Hub-Signature-256", "sha256=")
+}
+
+func parseGitHubPush(body []byte) (branch, sha, repoURL string, err error) {
+	var payload struct {
+		Ref        string `json:"ref"`
+		After      string `json:"after"`
+		Repository struct {
+			CloneURL string `json:"clone_url"`
+		} `json:"repository"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return "", "", "", err
+	}
+
+	return strings.TrimPrefix(payload.Ref, "refs/heads/"), payload.After, payload.Repository.CloneURL, nil
+}
@@ -0,0 +1,34 @@
+package webhook
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// verifyGitLabToken compares the X-Gitlab-Token header against the shared
+// secret directly — GitLab sends the token itself rather than an HMAC.
+func verifyGitLabToken(secret string, _ []byte, r *http.Request) error {
+	token := r.Header.Get("X-Gitlab-Token")
+	if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+		return fmt.Errorf("webhook: token mismatch")
+	}
+	return nil
+}
+
+func parseGitLabPush(body []byte) (branch, sha, repoURL string, err error) {
+	var payload struct {
+		Ref         string `json:"ref"`
+		CheckoutSHA string `json:"checkout_sha"`
+		Project     struct {
+			GitHTTPURL string `json:"git_http_url"`
+		} `json:"project"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return "", "", "", err
+	}
+
+	return strings.TrimPrefix(payload.Ref, "refs/heads/"), payload.CheckoutSHA, payload.Project.GitHTTPURL, nil
+}
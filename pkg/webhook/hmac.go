@@ -0,0 +1,44 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+)
+
+type verifyFunc func(secret string, body []byte, r *http.Request) error
+type parseFunc func(body []byte) (branch, sha, repoURL string, err error)
+
+// verifyHMACHeader recomputes an HMAC-SHA256 over body and compares it
+// against the header value found at headerName, stripping prefix first
+// (e.g. GitHub's "sha256=").
+func verifyHMACHeader(secret string, body []byte, r *http.Request, headerName, prefix string) error {
+	header := r.Header.Get(headerName)
+	if header == "" {
+		return fmt.Errorf("webhook: missing %s header", headerName)
+	}
+
+	digestHex := header
+	if prefix != "" {
+		if len(header) <= len(prefix) || header[:len(prefix)] != prefix {
+			return fmt.Errorf("webhook: malformed %s header", headerName)
+		}
+		digestHex = header[len(prefix):]
+	}
+
+	expected, err := hex.DecodeString(digestHex)
+	if err != nil {
+		return fmt.Errorf("webhook: malformed %s header", headerName)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return fmt.Errorf("webhook: signature mismatch")
+	}
+
+	return nil
+}
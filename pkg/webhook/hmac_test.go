@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func githubSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyGitHubSignature(t *testing.T) {
+	secret := "s3cr3t"
+	body := []byte(`{"ref":"refs/heads/main"}`)
+
+	valid := httptest.NewRequest(http.MethodPost, "/webhook/github/ns/name", nil)
+	valid.Header.Set("X-Hub-Signature-256", githubSignature(secret, body))
+	if err := verifyGitHubSignature(secret, body, valid); err != nil {
+		t.Errorf("expected a valid signature to verify, got %v", err)
+	}
+
+	wrongSecret := httptest.NewRequest(http.MethodPost, "/webhook/github/ns/name", nil)
+	wrongSecret.Header.Set("X-Hub-Signature-256", githubSignature("not-the-secret", body))
+	if err := verifyGitHubSignature(secret, body, wrongSecret); err == nil {
+		t.Error("expected a signature computed with the wrong secret to fail verification")
+	}
+
+	missing := httptest.NewRequest(http.MethodPost, "/webhook/github/ns/name", nil)
+	if err := verifyGitHubSignature(secret, body, missing); err == nil {
+		t.Error("expected a missing signature header to fail verification")
+	}
+
+	malformed := httptest.NewRequest(http.MethodPost, "/webhook/github/ns/name", nil)
+	malformed.Header.Set("X-Hub-Signature-256", "not-hex-and-no-prefix")
+	if err := verifyGitHubSignature(secret, body, malformed); err == nil {
+		t.Error("expected a malformed signature header to fail verification")
+	}
+}
+
+func TestVerifyGiteaSignature(t *testing.T) {
+	secret := "s3cr3t"
+	body := []byte(`{"ref":"refs/heads/main"}`)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	digest := hex.EncodeToString(mac.Sum(nil))
+
+	valid := httptest.NewRequest(http.MethodPost, "/webhook/gitea/ns/name", nil)
+	valid.Header.Set("X-Gitea-Signature", digest)
+	if err := verifyGiteaSignature(secret, body, valid); err != nil {
+		t.Errorf("expected a valid signature to verify, got %v", err)
+	}
+
+	tampered := httptest.NewRequest(http.MethodPost, "/webhook/gitea/ns/name", nil)
+	tampered.Header.Set("X-Gitea-Signature", digest)
+	if err := verifyGiteaSignature(secret, []byte(`{"ref":"refs/heads/evil"}`), tampered); err == nil {
+		t.Error("expected a signature over a different body to fail verification")
+	}
+}
+
+func TestVerifyGitLabToken(t *testing.T) {
+	secret := "s3cr3t"
+
+	valid := httptest.NewRequest(http.MethodPost, "/webhook/gitlab/ns/name", nil)
+	valid.Header.Set("X-Gitlab-Token", secret)
+	if err := verifyGitLabToken(secret, nil, valid); err != nil {
+		t.Errorf("expected a matching token to verify, got %v", err)
+	}
+
+	wrong := httptest.NewRequest(http.MethodPost, "/webhook/gitlab/ns/name", nil)
+	wrong.Header.Set("X-Gitlab-Token", "not-the-secret")
+	if err := verifyGitLabToken(secret, nil, wrong); err == nil {
+		t.Error("expected a mismatched token to fail verification")
+	}
+
+	missing := httptest.NewRequest(http.MethodPost, "/webhook/gitlab/ns/name", nil)
+	if err := verifyGitLabToken(secret, nil, missing); err == nil {
+		t.Error("expected a missing token header to fail verification")
+	}
+}
+
+func TestParseGitHubPush(t *testing.T) {
+	body := []byte(`{"ref":"refs/heads/main","after":"abc123","repository":{"clone_url":"https://github.com/o/r.git"}}`)
+
+	branch, sha, repoURL, err := parseGitHubPush(body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if branch != "main" || sha != "abc123" || repoURL != "https://github.com/o/r.git" {
+		t.Errorf("got branch=%q sha=%q repoURL=%q", branch, sha, repoURL)
+	}
+}
@@ -0,0 +1,136 @@
+// Package webhook implements an HTTP receiver for provider push events. A
+// validated event is translated into an Event and handed to a Handler,
+// which is expected to enqueue a reconcile for the matching CR with the
+// commit SHA already populated — so the reconcile loop only has to patch
+// status, without cloning the repository.
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxBodyBytes bounds how much of a webhook payload we'll read into memory.
+const maxBodyBytes = 1 << 20 // 1 MiB
+
+// Event is a push notification parsed from a provider-specific payload,
+// scoped to the GithubRepository whose webhook URL received it.
+type Event struct {
+	Provider  string
+	Namespace string
+	Name      string
+	RepoURL   string
+	Branch    string
+	CommitSHA string
+}
+
+// Handler is invoked with a validated Event.
+type Handler func(Event)
+
+// SecretLookup resolves the shared secret configured on the
+// spec.webhook.secretRef of the GithubRepository identified by namespace/name.
+type SecretLookup func(ctx context.Context, namespace, name string) (string, error)
+
+// Server exposes one HTTP endpoint per supported provider, each reachable at
+// /webhook/{provider}/{namespace}/{name} — the same path recorded in the
+// CR's status.webhookURL.
+type Server struct {
+	Addr    string
+	Secret  SecretLookup
+	Handler Handler
+}
+
+type provider struct {
+	verify verifyFunc
+	parse  parseFunc
+}
+
+var providers = map[string]provider{
+	"github": {verify: verifyGitHubSignature, parse: parseGitHubPush},
+	"gitlab": {verify: verifyGitLabToken, parse: parseGitLabPush},
+	"gitea":  {verify: verifyGiteaSignature, parse: parseGiteaPush},
+}
+
+// Routes builds the mux backing the server; split out so tests can exercise
+// it without binding a port.
+func (s *Server) Routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	for name, p := range providers {
+		name, p := name, p
+		mux.HandleFunc("/webhook/"+name+"/", func(w http.ResponseWriter, r *http.Request) {
+			s.handle(w, r, name, p)
+		})
+	}
+	return mux
+}
+
+// ListenAndServe starts the webhook HTTP server. It blocks until the server
+// stops or returns an error.
+func (s *Server) ListenAndServe() error {
+	return (&http.Server{Addr: s.Addr, Handler: s.Routes()}).ListenAndServe()
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request, name string, p provider) {
+	namespace, repoName, ok := namespacedNameFromPath(r.URL.Path, name)
+	if !ok {
+		http.Error(w, "invalid webhook path", http.StatusNotFound)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodyBytes))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	secret, err := s.Secret(r.Context(), namespace, repoName)
+	if err != nil {
+		http.Error(w, "unknown repository", http.StatusNotFound)
+		return
+	}
+
+	if err := p.verify(secret, body, r); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	branch, sha, repoURL, err := p.parse(body)
+	if err != nil {
+		http.Error(w, "failed to parse payload", http.StatusBadRequest)
+		return
+	}
+
+	s.Handler(Event{
+		Provider:  name,
+		Namespace: namespace,
+		Name:      repoName,
+		RepoURL:   repoURL,
+		Branch:    branch,
+		CommitSHA: sha,
+	})
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// namespacedNameFromPath extracts {namespace}/{name} from
+// /webhook/{provider}/{namespace}/{name}.
+func namespacedNameFromPath(path, provider string) (namespace, name string, ok bool) {
+	rest := strings.TrimPrefix(path, "/webhook/"+provider+"/")
+	if rest == path {
+		return "", "", false
+	}
+
+	parts := strings.SplitN(strings.Trim(rest, "/"), "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+
+	return parts[0], parts[1], true
+}
+
+// URL builds the status.webhookURL value for a repository served off baseURL.
+func URL(baseURL, provider, namespace, name string) string {
+	return strings.TrimSuffix(baseURL, "/") + "/webhook/" + provider + "/" + namespace + "/" + name
+}